@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	models "github.com/UncleJunVIP/ROMnibus/models"
+)
+
+// datTokenKind identifies the kind of lexical token produced by tokenizeCMPro.
+type datTokenKind int
+
+const (
+	datTokenIdent datTokenKind = iota
+	datTokenString
+	datTokenOpenParen
+	datTokenCloseParen
+)
+
+type datToken struct {
+	kind  datTokenKind
+	value string
+}
+
+// tokenizeCMPro lexes a CMPro-format DAT file into a flat token stream,
+// honoring quoted strings (with backslash escapes) and treating "(" / ")"
+// as their own tokens so the parser can track nesting depth.
+func tokenizeCMPro(content string) ([]datToken, error) {
+	var tokens []datToken
+
+	i, n := 0, len(content)
+	for i < n {
+		c := content[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, datToken{kind: datTokenOpenParen})
+			i++
+		case c == ')':
+			tokens = append(tokens, datToken{kind: datTokenCloseParen})
+			i++
+		case c == '"':
+			var sb strings.Builder
+			i++
+			for i < n && content[i] != '"' {
+				if content[i] == '\\' && i+1 < n {
+					i++
+				}
+				sb.WriteByte(content[i])
+				i++
+			}
+			if i >= n {
+				return nil, fmt.Errorf("unterminated quoted string")
+			}
+			i++ // consume closing quote
+			tokens = append(tokens, datToken{kind: datTokenString, value: sb.String()})
+		default:
+			start := i
+			for i < n && !strings.ContainsRune(" \t\r\n()", rune(content[i])) {
+				i++
+			}
+			tokens = append(tokens, datToken{kind: datTokenIdent, value: content[start:i]})
+		}
+	}
+
+	return tokens, nil
+}
+
+// datParser walks the token stream produced by tokenizeCMPro, recursively
+// descending into balanced "(" / ")" blocks.
+type datParser struct {
+	tokens []datToken
+	pos    int
+}
+
+// datBlock holds the scalar fields of a "game (...)" block plus the fields
+// of every nested "rom"/"disk" child block it contains.
+type datBlock struct {
+	fields map[string]string
+	roms   []map[string]string
+}
+
+func (p *datParser) peek() *datToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *datParser) next() *datToken {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+func (p *datParser) expect(kind datTokenKind) error {
+	t := p.next()
+	if t == nil || t.kind != kind {
+		return fmt.Errorf("unexpected token at position %d", p.pos)
+	}
+	return nil
+}
+
+// skipBlock consumes a balanced "( ... )" block without interpreting its
+// contents, used to skip over headers like "clrmamepro (...)".
+func (p *datParser) skipBlock() error {
+	if err := p.expect(datTokenOpenParen); err != nil {
+		return err
+	}
+
+	depth := 1
+	for depth > 0 {
+		t := p.next()
+		if t == nil {
+			return fmt.Errorf("unexpected end of input while skipping block")
+		}
+		switch t.kind {
+		case datTokenOpenParen:
+			depth++
+		case datTokenCloseParen:
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// parseScalarBlock parses a "( key value key value ... )" block whose values
+// are all scalars, as used by "rom (...)" and "disk (...)" children.
+func (p *datParser) parseScalarBlock() (map[string]string, error) {
+	if err := p.expect(datTokenOpenParen); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	for {
+		t := p.peek()
+		if t == nil {
+			return nil, fmt.Errorf("unexpected end of input in rom/disk block")
+		}
+		if t.kind == datTokenCloseParen {
+			p.next()
+			return fields, nil
+		}
+
+		key := p.next()
+		if key.kind != datTokenIdent {
+			return nil, fmt.Errorf("expected field name, got token kind %d", key.kind)
+		}
+
+		val := p.next()
+		if val == nil {
+			return nil, fmt.Errorf("expected value for field %s", key.value)
+		}
+
+		fields[strings.ToLower(key.value)] = val.value
+	}
+}
+
+// parseGameBlock parses a "game ( ... )" block, collecting its scalar fields
+// (e.g. name) and every "rom"/"disk" child it contains.
+func (p *datParser) parseGameBlock() (*datBlock, error) {
+	if err := p.expect(datTokenOpenParen); err != nil {
+		return nil, err
+	}
+
+	block := &datBlock{fields: make(map[string]string)}
+	for {
+		t := p.peek()
+		if t == nil {
+			return nil, fmt.Errorf("unexpected end of input in game block")
+		}
+		if t.kind == datTokenCloseParen {
+			p.next()
+			return block, nil
+		}
+
+		key := p.next()
+		if key.kind != datTokenIdent {
+			return nil, fmt.Errorf("expected field name, got token kind %d", key.kind)
+		}
+
+		switch strings.ToLower(key.value) {
+		case "rom", "disk":
+			romFields, err := p.parseScalarBlock()
+			if err != nil {
+				return nil, err
+			}
+			block.roms = append(block.roms, romFields)
+		default:
+			val := p.next()
+			if val == nil {
+				return nil, fmt.Errorf("expected value for field %s", key.value)
+			}
+			block.fields[strings.ToLower(key.value)] = val.value
+		}
+	}
+}
+
+// discSuffixRegex matches a trailing "(Disk N)" / "(Disc N of M)" marker so
+// multi-disk sets can be grouped back into one game entry.
+var discSuffixRegex = regexp.MustCompile(`(?i)\s*\(\s*(?:disk|disc)\s*\d+(?:\s*(?:of|/)\s*\d+)?\s*\)\s*$`)
+
+// groupMultiDiscGames merges game entries (adjacent or not) that differ only by a
+// "(Disk N of M)" suffix into a single game carrying all of their ROMs, so a
+// multi-disc set is stored as one game with many roms rather than one game
+// per disc.
+func groupMultiDiscGames(games []models.DATGame) []models.DATGame {
+	index := make(map[string]int)
+	result := make([]models.DATGame, 0, len(games))
+
+	for _, game := range games {
+		baseName := strings.TrimSpace(discSuffixRegex.ReplaceAllString(game.Name, ""))
+
+		key := baseName
+		if baseName == game.Name {
+			// Not a disc-based set; never merge it with anything else.
+			key = fmt.Sprintf("%s\x00%d", game.Name, len(result))
+		}
+
+		if i, ok := index[key]; ok {
+			result[i].ROMs = append(result[i].ROMs, game.ROMs...)
+			continue
+		}
+
+		merged := game
+		merged.Name = baseName
+		index[key] = len(result)
+		result = append(result, merged)
+	}
+
+	return result
+}
+
+// parseCMProDAT tokenizes and parses a CMPro-format DAT, skipping non-"game"
+// top-level blocks (e.g. the "clrmamepro" header), and groups multi-disc
+// sets into a single game entry.
+func parseCMProDAT(content string, platform string) ([]models.DATGame, error) {
+	tokens, err := tokenizeCMPro(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize DAT content: %w", err)
+	}
+
+	p := &datParser{tokens: tokens}
+	var games []models.DATGame
+
+	for {
+		t := p.peek()
+		if t == nil {
+			break
+		}
+		if t.kind != datTokenIdent {
+			return nil, fmt.Errorf("expected top-level keyword at token %d", p.pos)
+		}
+
+		keyword := strings.ToLower(t.value)
+		p.next()
+
+		if keyword != "game" {
+			if err := p.skipBlock(); err != nil {
+				return nil, fmt.Errorf("failed to skip %s block: %w", keyword, err)
+			}
+			continue
+		}
+
+		block, err := p.parseGameBlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse game block: %w", err)
+		}
+
+		game := models.DATGame{
+			Name:     block.fields["name"],
+			Platform: platform,
+		}
+		for _, romFields := range block.roms {
+			game.ROMs = append(game.ROMs, models.DATROM{
+				Name:   romFields["name"],
+				Size:   romFields["size"],
+				CRC:    strings.ToLower(romFields["crc"]),
+				MD5:    strings.ToLower(romFields["md5"]),
+				SHA1:   strings.ToLower(romFields["sha1"]),
+				SHA256: strings.ToLower(romFields["sha256"]),
+				Serial: romFields["serial"],
+			})
+		}
+
+		games = append(games, game)
+	}
+
+	return groupMultiDiscGames(games), nil
+}
+
+// logiqxDatafile mirrors the subset of the Logiqx XML DAT schema (used by
+// no-intro and TOSEC) that we need to extract games and their ROMs.
+type logiqxDatafile struct {
+	XMLName xml.Name     `xml:"datafile"`
+	Games   []logiqxGame `xml:"game"`
+}
+
+type logiqxGame struct {
+	Name string      `xml:"name,attr"`
+	ROMs []logiqxROM `xml:"rom"`
+}
+
+type logiqxROM struct {
+	Name   string `xml:"name,attr"`
+	Size   string `xml:"size,attr"`
+	CRC    string `xml:"crc,attr"`
+	MD5    string `xml:"md5,attr"`
+	SHA1   string `xml:"sha1,attr"`
+	SHA256 string `xml:"sha256,attr"`
+	Serial string `xml:"serial,attr"`
+}
+
+// parseLogiqxDAT parses a Logiqx-style XML DAT file.
+func parseLogiqxDAT(content []byte, platform string) ([]models.DATGame, error) {
+	var datafile logiqxDatafile
+	if err := xml.Unmarshal(content, &datafile); err != nil {
+		return nil, fmt.Errorf("failed to parse Logiqx XML DAT: %w", err)
+	}
+
+	games := make([]models.DATGame, 0, len(datafile.Games))
+	for _, g := range datafile.Games {
+		game := models.DATGame{Name: g.Name, Platform: platform}
+		for _, r := range g.ROMs {
+			game.ROMs = append(game.ROMs, models.DATROM{
+				Name:   r.Name,
+				Size:   r.Size,
+				CRC:    strings.ToLower(r.CRC),
+				MD5:    strings.ToLower(r.MD5),
+				SHA1:   strings.ToLower(r.SHA1),
+				SHA256: strings.ToLower(r.SHA256),
+				Serial: r.Serial,
+			})
+		}
+		games = append(games, game)
+	}
+
+	return groupMultiDiscGames(games), nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	models "github.com/UncleJunVIP/ROMnibus/models"
+)
+
+// sourceHasustra tags every game parsed from a Hasustra-style JSON export.
+const sourceHasustra = "hasustra"
+
+// hasustraRecord is one parsed Hasustra file: a single ROM set shared across
+// every platform its signatures reference. Keeping the platforms grouped
+// together - rather than fanning them out into independent DATGames right
+// away - lets the caller dedupe this record's ROMs once, instead of once per
+// platform, since the per-platform fan-out intentionally repeats the same
+// ROMs and isn't itself a duplicate.
+type hasustraRecord struct {
+	Name      string
+	Platforms []string
+	ROMs      []models.DATROM
+}
+
+// hasustraGameFile mirrors the subset of the Hasheous/Hasustra export schema
+// we need: a game's name, the platform(s) it was signed against, and its
+// ROMs (nested inside a generic "Attributes" list under the "ROMs" key).
+type hasustraGameFile struct {
+	Name                 string `json:"Name"`
+	SignatureDataObjects []struct {
+		Platform string `json:"Platform"`
+	} `json:"SignatureDataObjects"`
+	Attributes []struct {
+		AttributeName string          `json:"attributeName"`
+		Value         json.RawMessage `json:"Value"`
+	} `json:"Attributes"`
+}
+
+// parseHasustraDir reads every *.json file in dir and parses it into one
+// hasustraRecord per file.
+func parseHasustraDir(dir string) ([]hasustraRecord, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %s: %w", dir, err)
+	}
+
+	var records []hasustraRecord
+	for _, file := range files {
+		record, err := parseHasustraFile(file)
+		if err != nil {
+			fmt.Printf("Error parsing %s: %v\n", file, err)
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseHasustraFile parses a single Hasustra JSON export into one
+// hasustraRecord, sorting its platforms for deterministic downstream
+// insertion order.
+func parseHasustraFile(filename string) (hasustraRecord, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return hasustraRecord{}, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+
+	var data hasustraGameFile
+	if err := json.Unmarshal(content, &data); err != nil {
+		return hasustraRecord{}, fmt.Errorf("failed to parse JSON in %s: %w", filename, err)
+	}
+
+	roms := extractHasustraROMs(data)
+
+	platformSet := make(map[string]bool)
+	for _, sig := range data.SignatureDataObjects {
+		if sig.Platform != "" {
+			platformSet[sig.Platform] = true
+		}
+	}
+	if len(platformSet) == 0 {
+		platformSet[""] = true
+	}
+
+	platforms := make([]string, 0, len(platformSet))
+	for platform := range platformSet {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	return hasustraRecord{Name: data.Name, Platforms: platforms, ROMs: roms}, nil
+}
+
+// extractHasustraROMs pulls the "ROMs" attribute out of a Hasustra export.
+// The Value field is a loosely-typed JSON blob that's either a single ROM
+// object or an array of them, depending on the exporter version.
+func extractHasustraROMs(data hasustraGameFile) []models.DATROM {
+	var roms []models.DATROM
+
+	for _, attr := range data.Attributes {
+		if attr.AttributeName != "ROMs" {
+			continue
+		}
+
+		var romArray []map[string]interface{}
+		if err := json.Unmarshal(attr.Value, &romArray); err == nil {
+			for _, romObj := range romArray {
+				if rom, ok := hasustraROMFromMap(romObj); ok {
+					roms = append(roms, rom)
+				}
+			}
+			continue
+		}
+
+		var romObj map[string]interface{}
+		if err := json.Unmarshal(attr.Value, &romObj); err == nil {
+			if rom, ok := hasustraROMFromMap(romObj); ok {
+				roms = append(roms, rom)
+			}
+		}
+	}
+
+	return roms
+}
+
+func hasustraROMFromMap(romObj map[string]interface{}) (models.DATROM, bool) {
+	rom := models.DATROM{}
+
+	if name, ok := romObj["Name"].(string); ok {
+		rom.Name = name
+	}
+	if size, ok := romObj["Size"].(float64); ok {
+		rom.Size = strconv.FormatInt(int64(size), 10)
+	}
+	if crc, ok := romObj["Crc"].(string); ok {
+		rom.CRC = strings.ToLower(crc)
+	}
+	if md5, ok := romObj["Md5"].(string); ok {
+		rom.MD5 = strings.ToLower(md5)
+	}
+	if sha1, ok := romObj["Sha1"].(string); ok {
+		rom.SHA1 = strings.ToLower(sha1)
+	}
+	if sha256, ok := romObj["Sha256"].(string); ok {
+		rom.SHA256 = strings.ToLower(sha256)
+	}
+
+	if rom.CRC == "" && rom.MD5 == "" && rom.SHA1 == "" && rom.SHA256 == "" {
+		return models.DATROM{}, false
+	}
+
+	return rom, true
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	models "github.com/UncleJunVIP/ROMnibus/models"
+	"github.com/UncleJunVIP/ROMnibus/utils"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	tarballURL           = "https://codeload.github.com/libretro/libretro-database/tar.gz/refs/heads/master"
+	tarballRootPrefix    = "libretro-database-master/"
+	cacheSource          = "libretro-database"
+	tempDir              = "temp_libretro_db"
+	databaseFilename     = "ROMnibus.sqlite"
+	libretroSourcePrefix = "libretro-"
+)
+
+var datDirs = []string{
+	"metadat/no-intro",
+	"metadat/fbneo-split",
+}
+
+func main() {
+	hasustraDir := flag.String("hasustra-dir", "", "directory of Hasustra-style JSON exports to import alongside the libretro DATs")
+	flag.Parse()
+
+	db, err := utils.InitDB(databaseFilename)
+	if err != nil {
+		panic(err)
+	}
+	defer func(db *sql.DB) {
+		_ = db.Close()
+	}(db)
+
+	if err := utils.InitSchema(db, "sql/schema.sql"); err != nil {
+		panic(err)
+	}
+	fmt.Println("Database schema initialized successfully")
+
+	populateDB(db, *hasustraDir)
+}
+
+// datFileRef pairs a downloaded DAT file with the source label its parent
+// directory maps to, so games parsed from it can be tagged with provenance.
+type datFileRef struct {
+	Path   string
+	Source string
+}
+
+func populateDB(db *sql.DB, hasustraDir string) {
+	datFileRefs, changed, err := downloadDATFiles(db)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+
+	if changed {
+		if err := utils.ResetLibrary(db); err != nil {
+			panic(err)
+		}
+	}
+
+	gameMap := make(map[string][]models.DATGame)
+
+	for _, ref := range datFileRefs {
+		filename := filepath.Base(ref.Path)
+		fmt.Printf("Processing %s...\n", filename)
+
+		platform := parseFilename(filename)
+		games, err := parseDAT(ref.Path, platform)
+		if err != nil {
+			fmt.Printf("Error parsing %s: %v\n", filename, err)
+			continue
+		}
+
+		for i := range games {
+			games[i].Source = ref.Source
+		}
+
+		fmt.Printf("Parsed %d games from %s\n", len(games), filename)
+		gameMap[platform] = append(gameMap[platform], games...)
+	}
+
+	platforms := make([]string, 0, len(gameMap))
+	for platform := range gameMap {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	deduper := utils.NewDeduper()
+
+	totalGames := 0
+	for _, platform := range platforms {
+		fmt.Printf("Inserting games for platform: %s\n", platform)
+		inserted, err := insertGames(db, gameMap[platform], deduper)
+		if err != nil {
+			panic(err)
+		}
+		totalGames += inserted
+	}
+
+	if hasustraDir != "" {
+		records, err := parseHasustraDir(hasustraDir)
+		if err != nil {
+			fmt.Printf("Error parsing hasustra directory %s: %v\n", hasustraDir, err)
+		} else {
+			fmt.Printf("Parsed %d records from hasustra\n", len(records))
+			inserted, err := insertHasustraRecords(db, records, deduper)
+			if err != nil {
+				panic(err)
+			}
+			totalGames += inserted
+		}
+	}
+
+	fmt.Printf("Successfully inserted %d total games into database\n", totalGames)
+}
+
+// insertHasustraRecords inserts one game row per platform each hasustraRecord
+// references, sharing that record's surviving ROM set across every platform
+// sibling. Dedup (both the in-memory deduper and the DB check) runs once per
+// record rather than once per platform sibling, since the per-platform
+// fan-out intentionally repeats the same ROMs by design and isn't itself a
+// duplicate - only a ROM already known from an earlier record or an earlier
+// source is dropped. It returns the number of game rows inserted.
+func insertHasustraRecords(db *sql.DB, records []hasustraRecord, deduper utils.Deduper) (int, error) {
+	inserted := 0
+
+	for _, record := range records {
+		survivingROMs := make([]models.DATROM, 0, len(record.ROMs))
+		for _, rom := range record.ROMs {
+			if deduper.Seen(rom) {
+				continue
+			}
+
+			known, err := utils.RomExists(db, rom)
+			if err != nil {
+				return inserted, fmt.Errorf("failed to check for existing rom %s: %w", rom.Name, err)
+			}
+
+			deduper.Declare(rom)
+			if known {
+				continue
+			}
+
+			survivingROMs = append(survivingROMs, rom)
+		}
+		if len(survivingROMs) == 0 {
+			continue
+		}
+
+		for _, platform := range record.Platforms {
+			gameID, err := utils.UpsertGame(db, record.Name, platform, sourceHasustra)
+			if err != nil {
+				return inserted, err
+			}
+			for _, rom := range survivingROMs {
+				if err := utils.InsertROM(db, gameID, rom); err != nil {
+					return inserted, err
+				}
+			}
+			inserted++
+		}
+	}
+
+	return inserted, nil
+}
+
+// downloadDATFiles streams the libretro-database tarball over HTTPS and
+// extracts only the entries under datDirs, rather than shelling out to git
+// for a full clone. It sends If-None-Match against the ETag cached from the
+// last successful fetch, so a rerun against an unchanged upstream costs a
+// single round-trip and no tarball download. changed reports whether a
+// tarball was actually fetched (false on a 304), so the caller knows
+// whether to reconcile the database against fresh content.
+func downloadDATFiles(db *sql.DB) (files []datFileRef, changed bool, err error) {
+	_ = os.RemoveAll(tempDir)
+
+	etag, _, err := utils.GetCacheEntry(db, cacheSource)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, tarballURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to build request for %s: %w", tarballURL, err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	fmt.Println("Fetching libretro-database tarball...")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch %s: %w", tarballURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		fmt.Println("libretro-database is unchanged upstream, skipping download")
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status %s fetching %s", resp.Status, tarballURL)
+	}
+
+	gzipReader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gzipReader.Close()
+
+	datFiles, err := extractDATEntries(tar.NewReader(gzipReader))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := utils.SetCacheEntry(db, cacheSource, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")); err != nil {
+		return nil, false, fmt.Errorf("failed to persist cache entry: %w", err)
+	}
+
+	fmt.Printf("Found %d DAT files\n", len(datFiles))
+	return datFiles, true, nil
+}
+
+// extractDATEntries reads tarReader to completion, writing every entry that
+// falls under one of datDirs into tempDir and skipping everything else. It
+// warns if a configured datDir matched nothing, since upstream renaming or
+// removing a directory would otherwise silently drop an entire platform.
+func extractDATEntries(tarReader *tar.Reader) ([]datFileRef, error) {
+	var datFiles []datFileRef
+	matchCounts := make(map[string]int, len(datDirs))
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar stream: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		relPath := strings.TrimPrefix(header.Name, tarballRootPrefix)
+		if relPath == header.Name {
+			continue
+		}
+
+		datDir, source, ok := sourceForEntry(relPath)
+		if !ok {
+			continue
+		}
+
+		destPath := filepath.Join(tempDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(destPath), err)
+		}
+
+		if err := extractEntry(destPath, tarReader); err != nil {
+			return nil, err
+		}
+
+		datFiles = append(datFiles, datFileRef{Path: destPath, Source: source})
+		matchCounts[datDir]++
+	}
+
+	for _, datDir := range datDirs {
+		if matchCounts[datDir] == 0 {
+			fmt.Printf("Warning: found no .dat files under %s in the tarball\n", datDir)
+		}
+	}
+
+	return datFiles, nil
+}
+
+func extractEntry(destPath string, r io.Reader) error {
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", destPath, err)
+	}
+	defer destFile.Close()
+
+	if _, err := io.Copy(destFile, r); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", destPath, err)
+	}
+
+	return nil
+}
+
+// sourceForEntry reports whether relPath (a tar entry's path relative to the
+// repo root) falls under one of datDirs and is a .dat file, returning the
+// matched datDir and the source label to tag it with if so.
+func sourceForEntry(relPath string) (datDir string, source string, ok bool) {
+	if !strings.HasSuffix(strings.ToLower(relPath), ".dat") {
+		return "", "", false
+	}
+
+	for _, candidate := range datDirs {
+		if strings.HasPrefix(relPath, candidate+"/") {
+			return candidate, libretroSourcePrefix + filepath.Base(candidate), true
+		}
+	}
+
+	return "", "", false
+}
+
+func parseFilename(filename string) string {
+	openIndex := strings.Index(filename, "(")
+	if openIndex == -1 {
+		name := strings.TrimSuffix(filename, ".dat")
+		return strings.TrimSpace(name)
+	}
+
+	platform := strings.TrimSpace(filename[:openIndex])
+
+	closeIndex := strings.Index(filename[openIndex:], ")")
+	if closeIndex == -1 {
+		return platform
+	}
+
+	return platform
+}
+
+// parseDAT parses a DAT file into a slice of games, each carrying every ROM
+// it owns (and, for CMPro DATs, every disk; Logiqx <disk> elements are not
+// read). It auto-detects the Logiqx XML format and falls back to the CMPro
+// text format otherwise.
+func parseDAT(filename string, platform string) ([]models.DATGame, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(content))
+	if strings.HasPrefix(trimmed, "<?xml") || strings.HasPrefix(trimmed, "<datafile") {
+		return parseLogiqxDAT(content, platform)
+	}
+
+	return parseCMProDAT(trimmed, platform)
+}
+
+// insertGames inserts games and their ROMs inside a single transaction,
+// filtering each game's ROMs through deduper first and then, for anything
+// deduper hasn't seen this run, checking whether the DB already has it from
+// an earlier run (the in-memory deduper alone can't catch that, since it
+// starts empty every process invocation - contrast cmd/dir2dat, which always
+// checks the DB before inserting). A ROM already known either way is
+// skipped; a game left with no surviving ROMs is skipped entirely rather
+// than inserted empty. It returns the number of games actually inserted.
+func insertGames(db *sql.DB, games []models.DATGame, deduper utils.Deduper) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	gameStmt, err := tx.Prepare(`
+		INSERT OR IGNORE INTO games (name, platform, source)
+		VALUES (?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare game statement: %w", err)
+	}
+	defer func(stmt *sql.Stmt) {
+		_ = stmt.Close()
+	}(gameStmt)
+
+	gameIDStmt, err := tx.Prepare(`SELECT id FROM games WHERE name = ? AND platform = ?`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare game lookup statement: %w", err)
+	}
+	defer func(stmt *sql.Stmt) {
+		_ = stmt.Close()
+	}(gameIDStmt)
+
+	romStmt, err := tx.Prepare(`
+		INSERT INTO roms (game_id, name, size, crc, md5, sha1, sha256, serial)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare rom statement: %w", err)
+	}
+	defer func(stmt *sql.Stmt) {
+		_ = stmt.Close()
+	}(romStmt)
+
+	inserted := 0
+	for _, game := range games {
+		survivingROMs := make([]models.DATROM, 0, len(game.ROMs))
+		for _, rom := range game.ROMs {
+			if deduper.Seen(rom) {
+				continue
+			}
+
+			known, err := utils.RomExists(db, rom)
+			if err != nil {
+				return inserted, fmt.Errorf("failed to check for existing rom %s: %w", rom.Name, err)
+			}
+
+			deduper.Declare(rom)
+			if known {
+				continue
+			}
+
+			survivingROMs = append(survivingROMs, rom)
+		}
+		if len(survivingROMs) == 0 {
+			continue
+		}
+
+		if _, err := gameStmt.Exec(game.Name, game.Platform, game.Source); err != nil {
+			return inserted, fmt.Errorf("failed to insert game %s: %w", game.Name, err)
+		}
+
+		var gameID int64
+		if err := gameIDStmt.QueryRow(game.Name, game.Platform).Scan(&gameID); err != nil {
+			return inserted, fmt.Errorf("failed to look up id for game %s: %w", game.Name, err)
+		}
+
+		for _, rom := range survivingROMs {
+			if _, err := romStmt.Exec(gameID, rom.Name, rom.Size, rom.CRC, rom.MD5, rom.SHA1, rom.SHA256, rom.Serial); err != nil {
+				return inserted, fmt.Errorf("failed to insert rom %s for game %s: %w", rom.Name, game.Name, err)
+			}
+		}
+
+		inserted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return inserted, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return inserted, nil
+}
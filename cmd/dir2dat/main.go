@@ -0,0 +1,270 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/UncleJunVIP/ROMnibus/models"
+	"github.com/UncleJunVIP/ROMnibus/utils"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sourceArtificial tags games/roms that were discovered by walking a
+// directory rather than imported from a known DAT.
+const sourceArtificial = "artificial"
+
+func main() {
+	dbPath := flag.String("db", "ROMnibus.sqlite", "path to the SQLite database to seed")
+	outPath := flag.String("out", "dir2dat.dat", "path to write the generated DAT file")
+	platform := flag.String("platform", "", "platform to record against every discovered game")
+	datName := flag.String("name", "dir2dat", "DAT header name")
+	datDescription := flag.String("description", "Generated by dir2dat", "DAT header description")
+	datAuthor := flag.String("author", "ROMnibus", "DAT header author")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: dir2dat [flags] <romdir>")
+		os.Exit(1)
+	}
+	root := flag.Arg(0)
+
+	games, err := walkDirectory(root, *platform)
+	if err != nil {
+		panic(err)
+	}
+
+	db, err := utils.InitDB(*dbPath)
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if err := utils.InitSchema(db, "sql/schema.sql"); err != nil {
+		panic(err)
+	}
+
+	seeded, err := seedArtificialEntries(db, games, utils.NewDeduper())
+	if err != nil {
+		panic(err)
+	}
+
+	if err := writeDAT(*outPath, *datName, *datDescription, *datAuthor, games); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Wrote %d games (%d new ROMs seeded into %s) to %s\n", len(games), seeded, *dbPath, *outPath)
+}
+
+// walkDirectory recursively walks root, turning every top-level file or
+// folder into one DATGame: a top-level file becomes a single-ROM game, and a
+// top-level folder's contents - at any depth - become that game's ROMs.
+func walkDirectory(root string, platform string) ([]models.DATGame, error) {
+	topEntries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", root, err)
+	}
+
+	games := make([]models.DATGame, 0, len(topEntries))
+	for _, topEntry := range topEntries {
+		topPath := filepath.Join(root, topEntry.Name())
+
+		game := models.DATGame{
+			Name:     strings.TrimSuffix(topEntry.Name(), filepath.Ext(topEntry.Name())),
+			Platform: platform,
+			Source:   sourceArtificial,
+		}
+
+		if topEntry.IsDir() {
+			err := filepath.WalkDir(topPath, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+
+				rel, err := filepath.Rel(topPath, path)
+				if err != nil {
+					return err
+				}
+
+				roms, err := hashPath(path, rel)
+				if err != nil {
+					return err
+				}
+				game.ROMs = append(game.ROMs, roms...)
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk %s: %w", topPath, err)
+			}
+		} else {
+			roms, err := hashPath(topPath, topEntry.Name())
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash %s: %w", topPath, err)
+			}
+			game.ROMs = append(game.ROMs, roms...)
+		}
+
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+// hashPath hashes the file at path, expanding zip archives into one ROM per
+// entry - named by its in-zip path - rather than collapsing a multi-file
+// archive down to a single hash. displayName is used as the ROM name for
+// plain (non-zip) files.
+func hashPath(path, displayName string) ([]models.DATROM, error) {
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		entries, err := utils.HashZipEntries(path)
+		if err != nil {
+			return nil, err
+		}
+
+		roms := make([]models.DATROM, 0, len(entries))
+		for _, entry := range entries {
+			roms = append(roms, toDATROM(entry))
+		}
+		return roms, nil
+	}
+
+	entry, err := utils.HashFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entry.Name = displayName
+
+	return []models.DATROM{toDATROM(entry)}, nil
+}
+
+func toDATROM(entry utils.HashedEntry) models.DATROM {
+	return models.DATROM{
+		Name:   entry.Name,
+		Size:   strconv.FormatInt(entry.Size, 10),
+		CRC:    entry.Crc,
+		MD5:    entry.Md5,
+		SHA1:   entry.Sha1,
+		SHA256: entry.Sha256,
+	}
+}
+
+// seedArtificialEntries inserts every discovered ROM the database doesn't
+// already know about (by hash), tagging its game with source="artificial"
+// so hash lookups succeed against a user's own collection alongside the
+// libretro-database imports. deduper additionally collapses duplicate ROMs
+// encountered within this same walk (e.g. the same file copied twice).
+// It returns the number of ROMs inserted.
+func seedArtificialEntries(db *sql.DB, games []models.DATGame, deduper utils.Deduper) (int, error) {
+	seeded := 0
+
+	for _, game := range games {
+		var gameID int64
+		var gameInserted bool
+
+		for _, rom := range game.ROMs {
+			if deduper.Seen(rom) {
+				continue
+			}
+
+			known, err := utils.FindBySHA256(db, rom.SHA256)
+			if err != nil {
+				return seeded, fmt.Errorf("failed to look up %s: %w", rom.Name, err)
+			}
+			if known == nil {
+				known, err = utils.FindByHash(db, rom.SHA1)
+				if err != nil {
+					return seeded, fmt.Errorf("failed to look up %s: %w", rom.Name, err)
+				}
+			}
+			if known != nil {
+				deduper.Declare(rom)
+				continue
+			}
+
+			if !gameInserted {
+				id, err := utils.UpsertGame(db, game.Name, game.Platform, sourceArtificial)
+				if err != nil {
+					return seeded, fmt.Errorf("failed to insert game %s: %w", game.Name, err)
+				}
+				gameID = id
+				gameInserted = true
+			}
+
+			if err := utils.InsertROM(db, gameID, rom); err != nil {
+				return seeded, fmt.Errorf("failed to insert rom %s: %w", rom.Name, err)
+			}
+			deduper.Declare(rom)
+			seeded++
+		}
+	}
+
+	return seeded, nil
+}
+
+// logiqxDatafileOut, logiqxGameOut and logiqxROMOut mirror the subset of the
+// Logiqx XML DAT schema that dir2dat emits.
+type logiqxDatafileOut struct {
+	XMLName xml.Name        `xml:"datafile"`
+	Header  logiqxHeaderOut `xml:"header"`
+	Games   []logiqxGameOut `xml:"game"`
+}
+
+type logiqxHeaderOut struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	Author      string `xml:"author"`
+}
+
+type logiqxGameOut struct {
+	Name string         `xml:"name,attr"`
+	ROMs []logiqxROMOut `xml:"rom"`
+}
+
+type logiqxROMOut struct {
+	Name string `xml:"name,attr"`
+	Size string `xml:"size,attr"`
+	CRC  string `xml:"crc,attr"`
+	MD5  string `xml:"md5,attr"`
+	SHA1 string `xml:"sha1,attr"`
+}
+
+func writeDAT(path, name, description, author string, games []models.DATGame) error {
+	out := logiqxDatafileOut{
+		Header: logiqxHeaderOut{Name: name, Description: description, Author: author},
+	}
+
+	for _, game := range games {
+		gameOut := logiqxGameOut{Name: game.Name}
+		for _, rom := range game.ROMs {
+			gameOut.ROMs = append(gameOut.ROMs, logiqxROMOut{
+				Name: rom.Name,
+				Size: rom.Size,
+				CRC:  rom.CRC,
+				MD5:  rom.MD5,
+				SHA1: rom.SHA1,
+			})
+		}
+		out.Games = append(out.Games, gameOut)
+	}
+
+	data, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal DAT: %w", err)
+	}
+
+	content := append([]byte(xml.Header), data...)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write DAT file %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -1,15 +1,25 @@
 package models
 
+// DATGame represents a single game entry parsed from a DAT file, along with
+// every ROM (or disk) that belongs to it.
 type DATGame struct {
-	Name string
-	ROM  DATROM
+	ID       int64
+	Name     string
+	Platform string
+	Source   string
+	ROMs     []DATROM
 }
 
+// DATROM represents a single file belonging to a DATGame, identified by as
+// many hashes as the source DAT provided.
 type DATROM struct {
+	ID     int64
+	GameID int64
 	Name   string
 	Size   string
 	CRC    string
 	MD5    string
 	SHA1   string
 	SHA256 string
+	Serial string
 }
@@ -4,7 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"romnibus/models"
+	"os"
+	"strings"
+
+	"github.com/UncleJunVIP/ROMnibus/models"
 )
 
 var db *sql.DB
@@ -20,40 +23,309 @@ func CloseDB() error {
 	return db.Close()
 }
 
-func FindByHash(db *sql.DB, hash string) (*models.Game, error) {
+// InitSchema executes the schema file at schemaPath against db. The schema
+// uses CREATE TABLE/INDEX IF NOT EXISTS throughout, so this is safe to call
+// against a brand-new database or one that's already populated.
+func InitSchema(db *sql.DB, schemaPath string) error {
 	if db == nil {
-		return nil, errors.New("database is not initialized")
+		return errors.New("database is not initialized")
+	}
+
+	schemaContent, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", schemaPath, err)
+	}
+
+	if _, err := db.Exec(string(schemaContent)); err != nil {
+		return fmt.Errorf("failed to execute schema: %w", err)
 	}
 
-	query := `SELECT name, filename, platform, hash FROM games WHERE LOWER(hash) = LOWER(?) LIMIT 1`
+	return nil
+}
+
+const romColumns = `id, game_id, name, size, crc, md5, sha1, sha256, serial`
 
-	var game models.Game
-	err := db.QueryRow(query, hash).Scan(&game.Name, &game.Filename, &game.Platform, &game.Hash)
+func scanROM(row *sql.Row) (*models.DATROM, error) {
+	var rom models.DATROM
+	err := row.Scan(&rom.ID, &rom.GameID, &rom.Name, &rom.Size, &rom.CRC, &rom.MD5, &rom.SHA1, &rom.SHA256, &rom.Serial)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to query game by hash: %w", err)
+		return nil, err
 	}
 
-	return &game, nil
+	return &rom, nil
 }
 
-func FindByFilename(db *sql.DB, filename string) (*models.Game, error) {
+// FindByHash looks up a ROM by its SHA-1 hash.
+func FindByHash(db *sql.DB, hash string) (*models.DATROM, error) {
 	if db == nil {
 		return nil, errors.New("database is not initialized")
 	}
 
-	query := `SELECT name, filename, platform, hash FROM games WHERE LOWER(filename) = LOWER(?) LIMIT 1`
+	query := `SELECT ` + romColumns + ` FROM roms WHERE sha1 = ? LIMIT 1`
 
-	var game models.Game
-	err := db.QueryRow(query, filename).Scan(&game.Name, &game.Filename, &game.Platform, &game.Hash)
+	rom, err := scanROM(db.QueryRow(query, strings.ToLower(hash)))
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
+		return nil, fmt.Errorf("failed to query rom by hash: %w", err)
+	}
+
+	return rom, nil
+}
+
+// FindByFilename looks up a ROM by its filename.
+func FindByFilename(db *sql.DB, filename string) (*models.DATROM, error) {
+	if db == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	query := `SELECT ` + romColumns + ` FROM roms WHERE LOWER(name) = LOWER(?) LIMIT 1`
+
+	rom, err := scanROM(db.QueryRow(query, filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rom by filename: %w", err)
+	}
+
+	return rom, nil
+}
+
+// FindByCRC looks up a ROM by its CRC32 hash.
+func FindByCRC(db *sql.DB, crc string) (*models.DATROM, error) {
+	if db == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	query := `SELECT ` + romColumns + ` FROM roms WHERE crc = ? LIMIT 1`
+
+	rom, err := scanROM(db.QueryRow(query, strings.ToLower(crc)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rom by crc: %w", err)
+	}
+
+	return rom, nil
+}
+
+// FindByMD5 looks up a ROM by its MD5 hash.
+func FindByMD5(db *sql.DB, md5 string) (*models.DATROM, error) {
+	if db == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	query := `SELECT ` + romColumns + ` FROM roms WHERE md5 = ? LIMIT 1`
+
+	rom, err := scanROM(db.QueryRow(query, strings.ToLower(md5)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rom by md5: %w", err)
+	}
+
+	return rom, nil
+}
+
+// FindBySHA256 looks up a ROM by its SHA-256 hash.
+func FindBySHA256(db *sql.DB, sha256 string) (*models.DATROM, error) {
+	if db == nil {
+		return nil, errors.New("database is not initialized")
+	}
+
+	query := `SELECT ` + romColumns + ` FROM roms WHERE sha256 = ? LIMIT 1`
+
+	rom, err := scanROM(db.QueryRow(query, strings.ToLower(sha256)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rom by sha256: %w", err)
+	}
+
+	return rom, nil
+}
+
+// RomExists reports whether a stored rom matches rom's strongest populated
+// hash, picked in the same precedence as CompleteROM (SHA-256, then SHA-1,
+// then MD5, then CRC). Only that one hash is looked up; weaker hashes are not
+// tried when it finds nothing. A rom with no hash set at all is reported as
+// not existing, since it can't be looked up.
+func RomExists(db *sql.DB, rom models.DATROM) (bool, error) {
+	var found *models.DATROM
+	var err error
+
+	switch {
+	case rom.SHA256 != "":
+		found, err = FindBySHA256(db, rom.SHA256)
+	case rom.SHA1 != "":
+		found, err = FindByHash(db, rom.SHA1)
+	case rom.MD5 != "":
+		found, err = FindByMD5(db, rom.MD5)
+	case rom.CRC != "":
+		found, err = FindByCRC(db, rom.CRC)
+	default:
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check for existing rom %s: %w", rom.Name, err)
+	}
+
+	return found != nil, nil
+}
+
+// CompleteROM fills in every missing hash (and the name/size/serial) on rom
+// by looking it up via the strongest hash already populated - SHA-256, then
+// SHA-1, then MD5, then CRC - mirroring the romba-style CRC/MD5 -> SHA-1
+// lookup precedence. Only that one hash is looked up; if it matches nothing,
+// an error is returned without trying the weaker hashes.
+func CompleteROM(db *sql.DB, rom *models.DATROM) error {
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+	if rom == nil {
+		return errors.New("rom is nil")
+	}
+
+	var found *models.DATROM
+	var err error
+
+	switch {
+	case rom.SHA256 != "":
+		found, err = FindBySHA256(db, rom.SHA256)
+	case rom.SHA1 != "":
+		found, err = FindByHash(db, rom.SHA1)
+	case rom.MD5 != "":
+		found, err = FindByMD5(db, rom.MD5)
+	case rom.CRC != "":
+		found, err = FindByCRC(db, rom.CRC)
+	default:
+		return errors.New("rom has no hash set to complete from")
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to complete rom: %w", err)
+	}
+	if found == nil {
+		return errors.New("no matching rom found for the given hash")
+	}
+
+	if rom.ID == 0 {
+		rom.ID = found.ID
+	}
+	if rom.GameID == 0 {
+		rom.GameID = found.GameID
+	}
+	if rom.Name == "" {
+		rom.Name = found.Name
+	}
+	if rom.Size == "" {
+		rom.Size = found.Size
+	}
+	if rom.CRC == "" {
+		rom.CRC = found.CRC
+	}
+	if rom.MD5 == "" {
+		rom.MD5 = found.MD5
+	}
+	if rom.SHA1 == "" {
+		rom.SHA1 = found.SHA1
+	}
+	if rom.SHA256 == "" {
+		rom.SHA256 = found.SHA256
+	}
+	if rom.Serial == "" {
+		rom.Serial = found.Serial
+	}
+
+	return nil
+}
+
+// UpsertGame returns the id of the game row matching name+platform,
+// inserting it (tagged with source) first if it doesn't already exist.
+func UpsertGame(db *sql.DB, name, platform, source string) (int64, error) {
+	if db == nil {
+		return 0, errors.New("database is not initialized")
+	}
+
+	if _, err := db.Exec(`INSERT OR IGNORE INTO games (name, platform, source) VALUES (?, ?, ?)`, name, platform, source); err != nil {
+		return 0, fmt.Errorf("failed to insert game %s: %w", name, err)
+	}
+
+	var id int64
+	if err := db.QueryRow(`SELECT id FROM games WHERE name = ? AND platform = ?`, name, platform).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to look up id for game %s: %w", name, err)
+	}
+
+	return id, nil
+}
+
+// ResetLibrary deletes every libretro-sourced game (source LIKE 'libretro-%')
+// so a fresh import reconciles upstream renames/deletions instead of only
+// ever accumulating rows on top of stale ones. Their roms are deleted first
+// with an explicit DELETE, since the connection does not enable SQLite
+// foreign keys and so the schema's ON DELETE CASCADE never fires. Rows from
+// other sources (dir2dat's "artificial", the Hasustra importer's "hasustra")
+// are left untouched since they aren't reconciled against this tarball.
+func ResetLibrary(db *sql.DB) error {
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+
+	if _, err := db.Exec(`DELETE FROM roms WHERE game_id IN (SELECT id FROM games WHERE source LIKE 'libretro-%')`); err != nil {
+		return fmt.Errorf("failed to clear libretro roms: %w", err)
+	}
+
+	if _, err := db.Exec(`DELETE FROM games WHERE source LIKE 'libretro-%'`); err != nil {
+		return fmt.Errorf("failed to clear libretro games: %w", err)
+	}
+
+	return nil
+}
+
+// GetCacheEntry returns the ETag/Last-Modified cached for source from the
+// last successful fetch, or two empty strings if nothing is cached yet.
+func GetCacheEntry(db *sql.DB, source string) (etag string, lastModified string, err error) {
+	if db == nil {
+		return "", "", errors.New("database is not initialized")
+	}
+
+	row := db.QueryRow(`SELECT etag, last_modified FROM import_cache WHERE source = ?`, source)
+	if scanErr := row.Scan(&etag, &lastModified); scanErr != nil {
+		if errors.Is(scanErr, sql.ErrNoRows) {
+			return "", "", nil
 		}
-		return nil, fmt.Errorf("failed to query game by filename: %w", err)
+		return "", "", fmt.Errorf("failed to query cache entry for %s: %w", source, scanErr)
+	}
+
+	return etag, lastModified, nil
+}
+
+// SetCacheEntry records the ETag/Last-Modified headers an upstream source
+// returned, so a later run can send If-None-Match and skip re-fetching
+// content that hasn't changed.
+func SetCacheEntry(db *sql.DB, source, etag, lastModified string) error {
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO import_cache (source, etag, last_modified) VALUES (?, ?, ?)
+		ON CONFLICT (source) DO UPDATE SET etag = excluded.etag, last_modified = excluded.last_modified
+	`, source, etag, lastModified)
+	if err != nil {
+		return fmt.Errorf("failed to persist cache entry for %s: %w", source, err)
+	}
+
+	return nil
+}
+
+// InsertROM inserts a single rom row belonging to gameID.
+func InsertROM(db *sql.DB, gameID int64, rom models.DATROM) error {
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+
+	_, err := db.Exec(
+		`INSERT INTO roms (game_id, name, size, crc, md5, sha1, sha256, serial) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		gameID, rom.Name, rom.Size, rom.CRC, rom.MD5, rom.SHA1, rom.SHA256, rom.Serial,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert rom %s: %w", rom.Name, err)
 	}
 
-	return &game, nil
+	return nil
 }
@@ -0,0 +1,64 @@
+package utils
+
+import "github.com/UncleJunVIP/ROMnibus/models"
+
+// Deduper decides whether a ROM has already been imported, so that the same
+// physical ROM encountered via multiple sources (a DAT parser, the Hasustra
+// JSON importer, the dir2dat walker) is only ever inserted once.
+type Deduper interface {
+	// Seen reports whether an equivalent ROM has already been Declared.
+	Seen(rom models.DATROM) bool
+	// Declare records rom as imported.
+	Declare(rom models.DATROM)
+}
+
+// hashDeduper is the default Deduper. It keys a ROM on the strongest hash
+// it has available - SHA-256, then SHA-1, then MD5+size, then CRC+size -
+// mirroring the romba-style lookup precedence used by CompleteROM.
+type hashDeduper struct {
+	seen map[string]struct{}
+}
+
+// NewDeduper returns an empty Deduper ready to track ROMs across sources.
+func NewDeduper() Deduper {
+	return &hashDeduper{seen: make(map[string]struct{})}
+}
+
+func (d *hashDeduper) Seen(rom models.DATROM) bool {
+	key, ok := dedupeKey(rom)
+	if !ok {
+		return false
+	}
+
+	_, seen := d.seen[key]
+	return seen
+}
+
+func (d *hashDeduper) Declare(rom models.DATROM) {
+	key, ok := dedupeKey(rom)
+	if !ok {
+		return
+	}
+
+	d.seen[key] = struct{}{}
+}
+
+// dedupeKey returns the key used to recognize rom as a duplicate, and false
+// if rom has no hash to key on at all. A nodump/no-hash rom can't be
+// reliably recognized as a duplicate by filename alone - two unrelated
+// same-named files in different games (a readme.txt in two different games,
+// say) would otherwise collide - so it's always treated as distinct instead.
+func dedupeKey(rom models.DATROM) (string, bool) {
+	switch {
+	case rom.SHA256 != "":
+		return "sha256:" + rom.SHA256, true
+	case rom.SHA1 != "":
+		return "sha1:" + rom.SHA1, true
+	case rom.MD5 != "":
+		return "md5:" + rom.MD5 + ":" + rom.Size, true
+	case rom.CRC != "":
+		return "crc:" + rom.CRC + ":" + rom.Size, true
+	default:
+		return "", false
+	}
+}
@@ -2,58 +2,182 @@ package utils
 
 import (
 	"archive/zip"
+	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
+
+	"github.com/UncleJunVIP/ROMnibus/models"
 )
 
-// CalculateFileHash computes the SHA-1 hash of a specified file's contents or the first file in a zip archive.
-// Returns the hash as a hexadecimal string or an error if hash computation fails.
-func CalculateFileHash(filename string) (string, error) {
-	if strings.ToLower(filepath.Ext(filename)) == ".zip" {
-		return calculateZipHash(filename)
-	}
+// HashedEntry holds every hash ROMnibus tracks for a single file inside an
+// archive.
+type HashedEntry struct {
+	Name   string
+	Size   int64
+	Crc    string
+	Md5    string
+	Sha1   string
+	Sha256 string
+}
 
+// HashFile computes every hash ROMnibus tracks (CRC32, MD5, SHA-1, SHA-256)
+// for a single plain file in one pass. Use HashZipEntries for zip archives.
+func HashFile(filename string) (HashedEntry, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file %s: %w", filename, err)
+		return HashedEntry{}, fmt.Errorf("failed to open file %s: %w", filename, err)
 	}
 	defer file.Close()
 
-	hasher := sha1.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", fmt.Errorf("failed to calculate hash for %s: %w", filename, err)
+	info, err := file.Stat()
+	if err != nil {
+		return HashedEntry{}, fmt.Errorf("failed to stat file %s: %w", filename, err)
+	}
+
+	entry, err := hashReader(file)
+	if err != nil {
+		return HashedEntry{}, fmt.Errorf("failed to calculate hashes for %s: %w", filename, err)
 	}
 
-	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+	entry.Name = filepath.Base(filename)
+	entry.Size = info.Size()
+
+	return entry, nil
 }
 
-func calculateZipHash(filename string) (string, error) {
+// HashZipEntries hashes every entry in a zip archive (CRC32, MD5, SHA-1,
+// and SHA-256 in a single pass per entry), rather than just the first one,
+// so multi-file archives - CD-based sets, BIOS + program ROMs, interleaved
+// sets - are fully represented.
+func HashZipEntries(filename string) ([]HashedEntry, error) {
 	zipReader, err := zip.OpenReader(filename)
 	if err != nil {
-		return "", fmt.Errorf("failed to open zip file %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to open zip file %s: %w", filename, err)
 	}
 	defer zipReader.Close()
 
 	if len(zipReader.File) == 0 {
-		return "", fmt.Errorf("zip file %s is empty", filename)
+		return nil, fmt.Errorf("zip file %s is empty", filename)
 	}
 
-	zipFile := zipReader.File[0]
+	entries := make([]HashedEntry, 0, len(zipReader.File))
+	for _, zipFile := range zipReader.File {
+		if zipFile.FileInfo().IsDir() {
+			continue
+		}
 
+		entry, err := hashZipEntry(zipFile)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func hashZipEntry(zipFile *zip.File) (HashedEntry, error) {
 	fileReader, err := zipFile.Open()
 	if err != nil {
-		return "", fmt.Errorf("failed to open file %s within zip: %w", zipFile.Name, err)
+		return HashedEntry{}, fmt.Errorf("failed to open file %s within zip: %w", zipFile.Name, err)
 	}
 	defer fileReader.Close()
 
-	hasher := sha1.New()
-	if _, err := io.Copy(hasher, fileReader); err != nil {
-		return "", fmt.Errorf("failed to calculate hash for %s within zip: %w", zipFile.Name, err)
+	entry, err := hashReader(fileReader)
+	if err != nil {
+		return HashedEntry{}, fmt.Errorf("failed to calculate hashes for %s within zip: %w", zipFile.Name, err)
+	}
+
+	entry.Name = zipFile.Name
+
+	return entry, nil
+}
+
+// hashReader computes CRC32/MD5/SHA-1/SHA-256 over r in a single pass,
+// shared by both the plain-file and zip-entry hashing paths.
+func hashReader(r io.Reader) (HashedEntry, error) {
+	crcHasher := crc32.NewIEEE()
+	md5Hasher := md5.New()
+	sha1Hasher := sha1.New()
+	sha256Hasher := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(crcHasher, md5Hasher, sha1Hasher, sha256Hasher), r)
+	if err != nil {
+		return HashedEntry{}, err
+	}
+
+	return HashedEntry{
+		Size:   size,
+		Crc:    fmt.Sprintf("%08x", crcHasher.Sum32()),
+		Md5:    fmt.Sprintf("%x", md5Hasher.Sum(nil)),
+		Sha1:   fmt.Sprintf("%x", sha1Hasher.Sum(nil)),
+		Sha256: fmt.Sprintf("%x", sha256Hasher.Sum(nil)),
+	}, nil
+}
+
+// IdentifyArchive hashes every entry of the zip archive at path and looks
+// each one up by SHA-256, then SHA-1, then MD5, then CRC, stopping at the
+// first hash that matches a stored rom, and returns the distinct set
+// of known games any entry matched. An archive containing e.g. romA.bin and
+// romA.cue is correctly matched as a single game with two matching ROMs,
+// rather than reporting "unknown" just because entry 0 happens to be the
+// cue sheet.
+func IdentifyArchive(db *sql.DB, path string) ([]*models.DATGame, error) {
+	entries, err := HashZipEntries(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash archive %s: %w", path, err)
+	}
+
+	gamesByID := make(map[int64]*models.DATGame)
+	var order []int64
+
+	for _, entry := range entries {
+		rom, err := FindBySHA256(db, entry.Sha256)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up %s by sha256: %w", entry.Name, err)
+		}
+		if rom == nil {
+			rom, err = FindByHash(db, entry.Sha1)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up %s by sha1: %w", entry.Name, err)
+			}
+		}
+		if rom == nil {
+			rom, err = FindByMD5(db, entry.Md5)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up %s by md5: %w", entry.Name, err)
+			}
+		}
+		if rom == nil {
+			rom, err = FindByCRC(db, entry.Crc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up %s by crc: %w", entry.Name, err)
+			}
+		}
+		if rom == nil {
+			continue
+		}
+
+		game, ok := gamesByID[rom.GameID]
+		if !ok {
+			game = &models.DATGame{ID: rom.GameID}
+			gamesByID[rom.GameID] = game
+			order = append(order, rom.GameID)
+		}
+		game.ROMs = append(game.ROMs, *rom)
+	}
+
+	games := make([]*models.DATGame, 0, len(order))
+	for _, id := range order {
+		games = append(games, gamesByID[id])
 	}
 
-	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+	return games, nil
 }